@@ -0,0 +1,180 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	clientmetrics "k8s.io/client-go/tools/metrics"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// clientGoRequestMetricsClaimed tracks, process-wide, whether some Store has
+// already called InitClientGoMetrics. client-go's clientmetrics.Register is
+// itself backed by a package-level sync.Once, so a second call wouldn't
+// error - it would just silently leave its adapters pointed at a registry
+// nothing ever reports to. We'd rather fail loudly than let that happen.
+var clientGoRequestMetricsClaimed int32
+
+// resetClientGoMetricsClaims clears the process-wide claim flags below so a
+// test can assert its own "first claimant" behavior without depending on
+// which other test in this package happened to run first. It doesn't - and
+// can't - undo the underlying clientmetrics.Register/workqueue.SetProvider
+// calls, which are each backed by their own package-level sync.Once upstream;
+// it only resets our guard around them.
+func resetClientGoMetricsClaims() {
+	atomic.StoreInt32(&clientGoRequestMetricsClaimed, 0)
+	atomic.StoreInt32(&workqueueMetricsClaimed, 0)
+}
+
+// InitClientGoMetrics wires the client-go rest-client metrics into store's
+// registry so that kube-apiserver request latency and result codes show up
+// alongside the exporter's own metrics - without this, the exporter is blind
+// to whether backpressure comes from apiserver throttling rather than the
+// informer cache or the sinks.
+//
+// Only the first Store in a process to call this wins: client-go routes
+// these metrics through a single package-level hook, so it returns an error
+// rather than silently no-op'ing for every Store after the first.
+func InitClientGoMetrics(store *Store, name_prefix string) error {
+	if !atomic.CompareAndSwapInt32(&clientGoRequestMetricsClaimed, 0, 1) {
+		return fmt.Errorf("client-go request metrics are already wired to another Store in this process - client-go's metrics.Register hook is process-wide and first-wins")
+	}
+
+	factory := promauto.With(store.registry)
+
+	requestLatency := factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    name_prefix + "client_request_duration_seconds",
+		Help:    "Request latency in seconds, by verb and host, to the Kubernetes API server",
+		Buckets: sendDurationBuckets,
+	}, []string{"verb", "host"})
+
+	rateLimiterLatency := factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    name_prefix + "client_rate_limiter_duration_seconds",
+		Help:    "Client-side rate limiter latency in seconds, by verb and host, waiting for permission to send a request to the Kubernetes API server",
+		Buckets: sendDurationBuckets,
+	}, []string{"verb", "host"})
+
+	requestResult := factory.NewCounterVec(prometheus.CounterOpts{
+		Name: name_prefix + "client_requests_total",
+		Help: "Number of requests, partitioned by status code, method, and host, to the Kubernetes API server",
+	}, []string{"code", "method", "host"})
+
+	clientmetrics.Register(clientmetrics.RegisterOpts{
+		RequestLatency:     &latencyAdapter{histogram: requestLatency},
+		RateLimiterLatency: &latencyAdapter{histogram: rateLimiterLatency},
+		RequestResult:      &resultAdapter{counter: requestResult},
+	})
+	return nil
+}
+
+// latencyAdapter implements k8s.io/client-go/tools/metrics.LatencyMetric.
+type latencyAdapter struct {
+	histogram *prometheus.HistogramVec
+}
+
+func (l *latencyAdapter) Observe(_ context.Context, verb string, u url.URL, latency time.Duration) {
+	l.histogram.WithLabelValues(verb, u.Host).Observe(latency.Seconds())
+}
+
+// resultAdapter implements k8s.io/client-go/tools/metrics.ResultMetric.
+type resultAdapter struct {
+	counter *prometheus.CounterVec
+}
+
+func (r *resultAdapter) Increment(_ context.Context, code, method, host string) {
+	r.counter.WithLabelValues(code, method, host).Inc()
+}
+
+// workqueueMetricsClaimed tracks, process-wide, whether some Store has
+// already called InitWorkQueueMetrics. Like clientGoRequestMetricsClaimed
+// above, workqueue.SetProvider is itself backed by a package-level
+// sync.Once, so a second call would otherwise silently do nothing.
+var workqueueMetricsClaimed int32
+
+// InitWorkQueueMetrics wires the client-go workqueue metrics into store's
+// registry so that queue depth, add rate, latency and retries for the
+// informer's workqueue are visible alongside the exporter's own metrics.
+//
+// Only the first Store in a process to call this wins: workqueue routes
+// these metrics through a single package-level provider, so it returns an
+// error rather than silently no-op'ing for every Store after the first.
+func InitWorkQueueMetrics(store *Store, name_prefix string) error {
+	if !atomic.CompareAndSwapInt32(&workqueueMetricsClaimed, 0, 1) {
+		return fmt.Errorf("workqueue metrics are already wired to another Store in this process - workqueue.SetProvider is process-wide and first-wins")
+	}
+
+	workqueue.SetProvider(&workqueueMetricsProvider{
+		factory: promauto.With(store.registry),
+		prefix:  name_prefix,
+	})
+	return nil
+}
+
+// workqueueMetricsProvider implements k8s.io/client-go/util/workqueue.MetricsProvider.
+type workqueueMetricsProvider struct {
+	factory promauto.Factory
+	prefix  string
+}
+
+func (p *workqueueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return p.factory.NewGauge(prometheus.GaugeOpts{
+		Name:        p.prefix + "workqueue_depth",
+		Help:        "Current depth of the workqueue",
+		ConstLabels: prometheus.Labels{"name": name},
+	})
+}
+
+func (p *workqueueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return p.factory.NewCounter(prometheus.CounterOpts{
+		Name:        p.prefix + "workqueue_adds_total",
+		Help:        "Total number of adds handled by the workqueue",
+		ConstLabels: prometheus.Labels{"name": name},
+	})
+}
+
+func (p *workqueueMetricsProvider) NewLatencyMetric(name string) workqueue.HistogramMetric {
+	return p.factory.NewHistogram(prometheus.HistogramOpts{
+		Name:        p.prefix + "workqueue_queue_duration_seconds",
+		Help:        "How long an item stays in the workqueue before being requested",
+		Buckets:     sendDurationBuckets,
+		ConstLabels: prometheus.Labels{"name": name},
+	})
+}
+
+func (p *workqueueMetricsProvider) NewWorkDurationMetric(name string) workqueue.HistogramMetric {
+	return p.factory.NewHistogram(prometheus.HistogramOpts{
+		Name:        p.prefix + "workqueue_work_duration_seconds",
+		Help:        "How long it takes to process an item from the workqueue",
+		Buckets:     sendDurationBuckets,
+		ConstLabels: prometheus.Labels{"name": name},
+	})
+}
+
+func (p *workqueueMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return p.factory.NewGauge(prometheus.GaugeOpts{
+		Name:        p.prefix + "workqueue_unfinished_work_seconds",
+		Help:        "How long in seconds the outstanding workqueue items have been in flight",
+		ConstLabels: prometheus.Labels{"name": name},
+	})
+}
+
+func (p *workqueueMetricsProvider) NewLongestRunningProcessorSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return p.factory.NewGauge(prometheus.GaugeOpts{
+		Name:        p.prefix + "workqueue_longest_running_processor_seconds",
+		Help:        "How long the longest-running workqueue processor has been running",
+		ConstLabels: prometheus.Labels{"name": name},
+	})
+}
+
+func (p *workqueueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return p.factory.NewCounter(prometheus.CounterOpts{
+		Name:        p.prefix + "workqueue_retries_total",
+		Help:        "Total number of retries handled by the workqueue",
+		ConstLabels: prometheus.Labels{"name": name},
+	})
+}
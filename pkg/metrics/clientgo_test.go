@@ -0,0 +1,123 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	clientmetrics "k8s.io/client-go/tools/metrics"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestWorkqueueMetricsProviderCreatesMetrics(t *testing.T) {
+	p := &workqueueMetricsProvider{
+		factory: promauto.With(prometheus.NewRegistry()),
+		prefix:  "test_clientgo_",
+	}
+
+	if m := p.NewDepthMetric("informer"); m == nil {
+		t.Error("NewDepthMetric returned nil")
+	}
+	if m := p.NewAddsMetric("informer"); m == nil {
+		t.Error("NewAddsMetric returned nil")
+	}
+	if m := p.NewLatencyMetric("informer"); m == nil {
+		t.Error("NewLatencyMetric returned nil")
+	}
+	if m := p.NewWorkDurationMetric("informer"); m == nil {
+		t.Error("NewWorkDurationMetric returned nil")
+	}
+	if m := p.NewUnfinishedWorkSecondsMetric("informer"); m == nil {
+		t.Error("NewUnfinishedWorkSecondsMetric returned nil")
+	}
+	if m := p.NewLongestRunningProcessorSecondsMetric("informer"); m == nil {
+		t.Error("NewLongestRunningProcessorSecondsMetric returned nil")
+	}
+	if m := p.NewRetriesMetric("informer"); m == nil {
+		t.Error("NewRetriesMetric returned nil")
+	}
+}
+
+func TestInitWiresClientGoMetricsWhenEnabled(t *testing.T) {
+	store := NewMetricsStore("test_wire_clientgo_")
+	defer DestroyMetricsStore(store)
+
+	// Backend is deliberately set to something neither Prometheus nor StatsD
+	// recognize, so Init doesn't also start the metrics HTTP server or dial a
+	// StatsD client - only the registry wiring under test runs.
+	if _, err := Init(context.Background(), store, MetricsConfig{Backend: "none", ClientGoMetrics: true}); err != nil {
+		t.Fatalf("Init returned error: %v", err)
+	}
+
+	// Driving traffic through the client-go hooks that Init wires up should
+	// land on this store's registry, proving InitClientGoMetrics actually ran.
+	clientmetrics.RequestResult.Increment(context.Background(), "200", "GET", "https://example.com")
+	if count, err := testutil.GatherAndCount(store.registry, "test_wire_clientgo_client_requests_total"); err != nil {
+		t.Fatalf("GatherAndCount returned error: %v", err)
+	} else if count == 0 {
+		t.Error("expected Init to register client-go request-result metrics on the store's registry")
+	}
+
+	// Likewise for the workqueue provider: creating a queue should report
+	// its depth on this store's registry rather than being a no-op.
+	q := workqueue.NewNamed("wiring-test")
+	defer q.ShutDown()
+	q.Add("item")
+	if count, err := testutil.GatherAndCount(store.registry, "test_wire_clientgo_workqueue_depth"); err != nil {
+		t.Fatalf("GatherAndCount returned error: %v", err)
+	} else if count == 0 {
+		t.Error("expected Init to register workqueue metrics on the store's registry")
+	}
+}
+
+func TestInitClientGoMetricsReturnsErrorForSecondStore(t *testing.T) {
+	// The claim flags are process-wide and never reset by production code,
+	// so no test in this package can assume it's the first claimant. Reset
+	// them here and restore afterwards so this test's "first store" claim
+	// below is deterministic regardless of what else in the package ran.
+	resetClientGoMetricsClaims()
+	t.Cleanup(resetClientGoMetricsClaims)
+
+	firstStore := NewMetricsStore("test_clientgo_first_store_")
+	defer DestroyMetricsStore(firstStore)
+	if err := InitClientGoMetrics(firstStore, "test_clientgo_first_store_"); err != nil {
+		t.Fatalf("InitClientGoMetrics on first store returned error: %v", err)
+	}
+	if err := InitWorkQueueMetrics(firstStore, "test_clientgo_first_store_"); err != nil {
+		t.Fatalf("InitWorkQueueMetrics on first store returned error: %v", err)
+	}
+
+	// A second Store enabling ClientGoMetrics must get a clear error back
+	// instead of silently wiring its adapters to a registry nothing ever
+	// reports to.
+	store := NewMetricsStore("test_clientgo_second_store_")
+	defer DestroyMetricsStore(store)
+
+	if err := InitClientGoMetrics(store, "test_clientgo_second_store_"); err == nil {
+		t.Error("expected InitClientGoMetrics to error when client-go request metrics are already claimed by another Store")
+	}
+	if err := InitWorkQueueMetrics(store, "test_clientgo_second_store_"); err == nil {
+		t.Error("expected InitWorkQueueMetrics to error when workqueue metrics are already claimed by another Store")
+	}
+
+	if _, err := Init(context.Background(), store, MetricsConfig{Backend: "none", ClientGoMetrics: true}); err == nil {
+		t.Error("expected Init to propagate the already-claimed error instead of returning success")
+	}
+}
+
+func TestInitSkipsClientGoMetricsWhenDisabled(t *testing.T) {
+	store := NewMetricsStore("test_wire_clientgo_disabled_")
+	defer DestroyMetricsStore(store)
+
+	if _, err := Init(context.Background(), store, MetricsConfig{Backend: "none"}); err != nil {
+		t.Fatalf("Init returned error: %v", err)
+	}
+
+	if count, err := testutil.GatherAndCount(store.registry, "test_wire_clientgo_disabled_client_requests_total"); err != nil {
+		t.Fatalf("GatherAndCount returned error: %v", err)
+	} else if count != 0 {
+		t.Error("expected client-go request-result metrics to be absent when ClientGoMetrics is disabled")
+	}
+}
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// rateWindowMinutes is the size of the ring buffer backing eventRateTracker,
+// wide enough to serve the longest window (15m) we report a rate for.
+const rateWindowMinutes = 15
+
+// eventRateTracker is a small ring buffer of one-minute buckets used to
+// compute how many events were processed within a trailing window, without
+// having to keep every individual timestamp around.
+type eventRateTracker struct {
+	mu      sync.Mutex
+	buckets [rateWindowMinutes]int64
+	times   [rateWindowMinutes]time.Time
+	idx     int
+}
+
+// Record marks one event as processed at now.
+func (t *eventRateTracker) Record(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rotate(now)
+	t.buckets[t.idx]++
+}
+
+// rotate advances to a fresh bucket if the current one is more than a
+// minute old. Buckets that fall out of whatever window a caller later asks
+// about are simply skipped by Count, based on their timestamp.
+func (t *eventRateTracker) rotate(now time.Time) {
+	if t.times[t.idx].IsZero() || now.Sub(t.times[t.idx]) >= time.Minute {
+		t.idx = (t.idx + 1) % rateWindowMinutes
+		t.buckets[t.idx] = 0
+		t.times[t.idx] = now
+	}
+}
+
+// Count returns the number of events recorded within window of now.
+func (t *eventRateTracker) Count(now time.Time, window time.Duration) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cutoff := now.Add(-window)
+	var sum int64
+	for i, ts := range t.times {
+		if !ts.IsZero() && ts.After(cutoff) {
+			sum += t.buckets[i]
+		}
+	}
+	return sum
+}
+
+// Rate returns the average events-processed-per-minute over window of now.
+func (t *eventRateTracker) Rate(now time.Time, window time.Duration) float64 {
+	minutes := window.Minutes()
+	if minutes <= 0 {
+		return 0
+	}
+	return float64(t.Count(now, window)) / minutes
+}
+
+// ReadinessConfig tunes when Store.isReady flips to NotReady based on the
+// windowed event rate, instead of flapping on the first slow minute.
+type ReadinessConfig struct {
+	// RateFloor is the minimum events-processed-per-minute rate, measured
+	// over RateWindow, below which the exporter is considered unhealthy.
+	// Zero uses the default floor of 0.2 events per minute (at least one
+	// event within the default 5-minute RateWindow). Set it negative to disable the rate-based check
+	// entirely (the exporter is then always ready).
+	RateFloor float64
+	// RateWindow is the trailing window the rate is computed over. Defaults
+	// to 5 minutes if zero.
+	RateWindow time.Duration
+	// GracePeriod is how long the rate must stay below RateFloor before
+	// readiness actually flips to NotReady. Defaults to 5 minutes if zero.
+	GracePeriod time.Duration
+}
+
+// defaultReadinessConfig is what each Store starts out with, and what
+// SetReadinessConfig falls back to for any zero field. The default RateFloor
+// mirrors the pre-windowed-rate behavior of flipping NotReady once nothing
+// has been processed in the last RateWindow, so the /-/ready endpoint does
+// something meaningful even if the caller never touches ReadinessConfig.
+var defaultReadinessConfig = ReadinessConfig{
+	RateFloor:   1.0 / 5,
+	RateWindow:  5 * time.Minute,
+	GracePeriod: 5 * time.Minute,
+}
+
+// SetReadinessConfig overrides this Store's readiness thresholds. It's safe
+// to call concurrently with isReady - e.g. from the /-/ready handler on one
+// goroutine while a caller reconfigures thresholds on another.
+func (s *Store) SetReadinessConfig(cfg ReadinessConfig) {
+	if cfg.RateFloor == 0 {
+		cfg.RateFloor = defaultReadinessConfig.RateFloor
+	}
+	if cfg.RateWindow <= 0 {
+		cfg.RateWindow = defaultReadinessConfig.RateWindow
+	}
+	if cfg.GracePeriod <= 0 {
+		cfg.GracePeriod = defaultReadinessConfig.GracePeriod
+	}
+
+	s.readinessMu.Lock()
+	defer s.readinessMu.Unlock()
+	s.readinessConfig = cfg
+	s.belowFloorSince = time.Time{}
+}
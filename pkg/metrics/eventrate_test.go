@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEventRateTrackerCount(t *testing.T) {
+	tracker := &eventRateTracker{}
+	base := time.Now()
+
+	tracker.Record(base)
+	tracker.Record(base.Add(30 * time.Second))
+	tracker.Record(base.Add(10 * time.Minute))
+
+	if got := tracker.Count(base.Add(10*time.Minute), time.Minute); got != 1 {
+		t.Errorf("expected 1 event within the last minute, got %d", got)
+	}
+	if got := tracker.Count(base.Add(10*time.Minute), 15*time.Minute); got != 3 {
+		t.Errorf("expected 3 events within the last 15 minutes, got %d", got)
+	}
+}
+
+func TestEventRateTrackerRate(t *testing.T) {
+	tracker := &eventRateTracker{}
+	base := time.Now()
+
+	for i := 0; i < 5; i++ {
+		tracker.Record(base)
+	}
+
+	if got := tracker.Rate(base, 5*time.Minute); got != 1 {
+		t.Errorf("expected rate of 1 event/minute over a 5 minute window, got %v", got)
+	}
+}
+
+func TestIsEventExporterReadyRespectsGracePeriod(t *testing.T) {
+	store := NewMetricsStore("test_readiness_")
+	defer DestroyMetricsStore(store)
+
+	store.SetReadinessConfig(ReadinessConfig{RateFloor: 1, RateWindow: time.Minute, GracePeriod: time.Hour})
+
+	if !store.isReady() {
+		t.Error("expected exporter to still be ready within the grace period even with a zero rate")
+	}
+}
+
+func TestIsEventExporterReadyWithinStartupGraceByDefault(t *testing.T) {
+	store := NewMetricsStore("test_readiness_default_")
+	defer DestroyMetricsStore(store)
+
+	if !store.isReady() {
+		t.Error("expected exporter to be ready during the default grace period, even with the default non-zero RateFloor")
+	}
+}
+
+func TestIsEventExporterReadyFlipsAfterDefaultGraceWithNoEvents(t *testing.T) {
+	store := NewMetricsStore("test_readiness_default_flip_")
+	defer DestroyMetricsStore(store)
+
+	store.SetReadinessConfig(ReadinessConfig{RateWindow: time.Minute, GracePeriod: time.Millisecond})
+
+	store.isReady()                  // starts the below-floor grace timer
+	time.Sleep(5 * time.Millisecond) // let the grace period elapse
+	if store.isReady() {
+		t.Error("expected exporter to flip not-ready once the default RateFloor is unmet past the grace period")
+	}
+}
+
+func TestReadinessRateFloorNegativeDisablesCheck(t *testing.T) {
+	store := NewMetricsStore("test_readiness_disabled_")
+	defer DestroyMetricsStore(store)
+
+	store.SetReadinessConfig(ReadinessConfig{RateFloor: -1, GracePeriod: time.Millisecond})
+
+	if !store.isReady() {
+		t.Error("expected exporter to always be ready when RateFloor is negative")
+	}
+}
+
+func TestReadinessConfigIsPerStore(t *testing.T) {
+	// Two Stores must not share readiness state: flipping one below its
+	// floor shouldn't affect the other, and each is free to run its own
+	// readiness probe concurrently (see TestIsReadyConcurrentSafe).
+	storeA := NewMetricsStore("test_readiness_iso_a_")
+	defer DestroyMetricsStore(storeA)
+	storeB := NewMetricsStore("test_readiness_iso_b_")
+	defer DestroyMetricsStore(storeB)
+
+	storeA.SetReadinessConfig(ReadinessConfig{RateFloor: 1000, RateWindow: time.Minute, GracePeriod: time.Millisecond})
+	storeA.isReady()                 // starts the below-floor grace timer
+	time.Sleep(5 * time.Millisecond) // let the grace period elapse
+	if storeA.isReady() {
+		t.Error("expected storeA to be not-ready once below its floor past the grace period")
+	}
+	if !storeB.isReady() {
+		t.Error("expected storeB to be unaffected by storeA's readiness config")
+	}
+}
+
+func TestInitWiresReadinessConfig(t *testing.T) {
+	store := NewMetricsStore("test_wire_readiness_")
+	defer DestroyMetricsStore(store)
+
+	// Backend is set to something neither Prometheus nor StatsD recognizes,
+	// so Init doesn't also start the metrics HTTP server or dial a StatsD
+	// client - only the readiness wiring under test runs.
+	cfg := MetricsConfig{Backend: "none", Readiness: ReadinessConfig{RateFloor: 1000, RateWindow: time.Minute, GracePeriod: time.Millisecond}}
+	if _, err := Init(context.Background(), store, cfg); err != nil {
+		t.Fatalf("Init returned error: %v", err)
+	}
+
+	store.isReady()                  // starts the below-floor grace timer
+	time.Sleep(5 * time.Millisecond) // let the grace period elapse
+	if store.isReady() {
+		t.Error("expected Init to wire cfg.Readiness into the store, flipping not-ready past its grace period")
+	}
+}
+
+func TestInitAppliesReadinessDefaultsWhenUnconfigured(t *testing.T) {
+	store := NewMetricsStore("test_wire_readiness_default_")
+	defer DestroyMetricsStore(store)
+
+	if _, err := Init(context.Background(), store, MetricsConfig{Backend: "none"}); err != nil {
+		t.Fatalf("Init returned error: %v", err)
+	}
+
+	if !store.isReady() {
+		t.Error("expected exporter to be ready during the default grace period even with an unconfigured Readiness")
+	}
+}
+
+func TestIsReadyConcurrentSafe(t *testing.T) {
+	store := NewMetricsStore("test_readiness_concurrent_")
+	defer DestroyMetricsStore(store)
+
+	store.SetReadinessConfig(ReadinessConfig{RateFloor: 1, RateWindow: time.Minute, GracePeriod: time.Millisecond})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			store.isReady()
+		}()
+	}
+	wg.Wait()
+}
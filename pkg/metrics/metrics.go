@@ -1,8 +1,11 @@
 package metrics
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -14,18 +17,181 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-var globalMetricsStore *Store
-var lastEventProcessedTime time.Time
+// eventLabels is the common label set shared by the per-event counters so
+// that callers in the sinks/router don't need to know the label order.
+var eventLabels = []string{"receiver", "event_type", "namespace", "reason"}
+
+// sendDurationBuckets are sensible default buckets for network I/O latency,
+// from 5ms up to roughly 10s.
+var sendDurationBuckets = prometheus.ExponentialBuckets(0.005, 2, 12)
 
 type Store struct {
-	EventsProcessed             prometheus.Counter
-	EventsDiscarded             prometheus.Counter
-	WatchErrors                 prometheus.Counter
-	SendErrors                  prometheus.Counter
-	BuildInfo                   prometheus.GaugeFunc
-	KubeApiReadCacheHits        prometheus.Counter
-	KubeApiReadRequests         prometheus.Counter
-	LastProcessedEventTimestamp prometheus.Gauge
+	EventsProcessed                *prometheus.CounterVec
+	EventsDiscarded                *prometheus.CounterVec
+	WatchErrors                    prometheus.Counter
+	SendErrors                     *prometheus.CounterVec
+	BuildInfo                      prometheus.GaugeFunc
+	KubeApiReadCacheHits           prometheus.Counter
+	KubeApiReadRequests            prometheus.Counter
+	LastProcessedEventTimestamp    prometheus.Gauge
+	SendDurationSeconds            *prometheus.HistogramVec
+	EventProcessingDurationSeconds *prometheus.HistogramVec
+	SendRetriesTotal               *prometheus.CounterVec
+	SendRetryBackoffSeconds        *prometheus.HistogramVec
+	EventsProcessedRate1m          prometheus.Gauge
+	EventsProcessedRate5m          prometheus.Gauge
+	EventsProcessedRate15m         prometheus.Gauge
+
+	// registry is the Store's own registry, rather than the global
+	// prometheus.DefaultRegisterer, so that multiple Stores - in tests, or
+	// in a binary embedding this package - don't collide on metric names.
+	registry *prometheus.Registry
+	// prefix is the name_prefix the Store was created with, remembered so
+	// that Init can pass it on to InitClientGoMetrics/InitWorkQueueMetrics
+	// without callers having to repeat it.
+	prefix  string
+	rate    *eventRateTracker
+	stopped chan struct{}
+	// destroyOnce guards stopped so that calling DestroyMetricsStore more
+	// than once - e.g. a caller that both defers it and calls it explicitly
+	// on an error path - doesn't panic closing an already-closed channel.
+	destroyOnce sync.Once
+
+	// readinessMu guards readinessConfig and belowFloorSince, which isReady
+	// reads and mutates on every /-/ready probe - concurrent requests would
+	// otherwise race on a plain time.Time.
+	readinessMu     sync.Mutex
+	readinessConfig ReadinessConfig
+	belowFloorSince time.Time
+}
+
+// HTTPHandler returns an http.Handler serving this Store's metrics, bound to
+// its own registry rather than the global one.
+func (s *Store) HTTPHandler() http.Handler {
+	return promhttp.HandlerFor(s.registry, promhttp.HandlerOpts{
+		// Opt into OpenMetrics to support exemplars.
+		EnableOpenMetrics: true,
+	})
+}
+
+// isReady reports whether this Store's windowed event-processing rate is
+// healthy. It only flips to false once the rate has stayed below
+// readinessConfig.RateFloor for readinessConfig.GracePeriod, so a single slow
+// minute on a low-volume cluster doesn't flap readiness. Safe for concurrent
+// use, since the /-/ready handler may be hit by several probes at once.
+func (s *Store) isReady() bool {
+	s.readinessMu.Lock()
+	defer s.readinessMu.Unlock()
+
+	if s.readinessConfig.RateFloor < 0 {
+		return true
+	}
+
+	now := time.Now()
+	rate := s.rate.Rate(now, s.readinessConfig.RateWindow)
+	if rate >= s.readinessConfig.RateFloor {
+		s.belowFloorSince = time.Time{}
+		return true
+	}
+
+	if s.belowFloorSince.IsZero() {
+		s.belowFloorSince = now
+	}
+	return now.Sub(s.belowFloorSince) < s.readinessConfig.GracePeriod
+}
+
+// IncEventsProcessed increments the events-processed counter for the given
+// receiver, event type (Normal/Warning), namespace and reason.
+func (s *Store) IncEventsProcessed(receiver, eventType, namespace, reason string) {
+	s.EventsProcessed.WithLabelValues(receiver, eventType, namespace, reason).Inc()
+	s.rate.Record(time.Now())
+}
+
+// IncEventsDiscarded increments the events-discarded counter for the given
+// receiver, event type (Normal/Warning), namespace and reason.
+func (s *Store) IncEventsDiscarded(receiver, eventType, namespace, reason string) {
+	s.EventsDiscarded.WithLabelValues(receiver, eventType, namespace, reason).Inc()
+}
+
+// IncSendErrors increments the send-errors counter for the given receiver,
+// event type (Normal/Warning), namespace and reason.
+func (s *Store) IncSendErrors(receiver, eventType, namespace, reason string) {
+	s.SendErrors.WithLabelValues(receiver, eventType, namespace, reason).Inc()
+}
+
+// ObserveSend records how long a single sink Send call took, labeled by
+// receiver and result (e.g. "success"/"error").
+func (s *Store) ObserveSend(receiver, result string, d time.Duration) {
+	s.SendDurationSeconds.WithLabelValues(receiver, result).Observe(d.Seconds())
+}
+
+// StartTimer returns a closure that, when called, observes the elapsed time
+// since StartTimer was invoked as an EventProcessingDurationSeconds sample
+// for the given receiver. It lets callers bracket a send operation with
+// `defer store.StartTimer(receiver)()` instead of managing a time.Time by hand.
+func (s *Store) StartTimer(receiver string) func() {
+	start := time.Now()
+	return func() {
+		s.EventProcessingDurationSeconds.WithLabelValues(receiver).Observe(time.Since(start).Seconds())
+	}
+}
+
+// IncSendRetries increments the retry counter for the given receiver and
+// attempt number (1-based). Call it from the same retry loop that
+// eventually calls IncSendErrors so operators can tell a healthy-but-retrying
+// sink apart from one that's actually failing.
+func (s *Store) IncSendRetries(receiver string, attempt int) {
+	s.SendRetriesTotal.WithLabelValues(receiver, strconv.Itoa(attempt)).Inc()
+}
+
+// ObserveSendRetryBackoff records the backoff duration waited before a retry
+// attempt for the given receiver.
+func (s *Store) ObserveSendRetryBackoff(receiver string, d time.Duration) {
+	s.SendRetryBackoffSeconds.WithLabelValues(receiver).Observe(d.Seconds())
+}
+
+// Store implements Sink by dispatching on a metric's logical name (the
+// metric name without the configured prefix) so that callers can write
+// through the generic Sink interface without caring whether Prometheus,
+// StatsD, or both are configured as the backend.
+var _ Sink = (*Store)(nil)
+
+func (s *Store) Inc(name string, tags map[string]string) {
+	switch name {
+	case "events_sent":
+		s.IncEventsProcessed(tags["receiver"], tags["event_type"], tags["namespace"], tags["reason"])
+	case "events_discarded":
+		s.IncEventsDiscarded(tags["receiver"], tags["event_type"], tags["namespace"], tags["reason"])
+	case "send_event_errors":
+		s.IncSendErrors(tags["receiver"], tags["event_type"], tags["namespace"], tags["reason"])
+	case "send_retries_total":
+		attempt, _ := strconv.Atoi(tags["attempt"])
+		s.IncSendRetries(tags["receiver"], attempt)
+	case "watch_errors":
+		s.WatchErrors.Inc()
+	case "kube_api_read_cache_hits":
+		s.KubeApiReadCacheHits.Inc()
+	case "kube_api_read_cache_misses":
+		s.KubeApiReadRequests.Inc()
+	}
+}
+
+func (s *Store) Observe(name string, v float64, tags map[string]string) {
+	switch name {
+	case "send_duration_seconds":
+		s.SendDurationSeconds.WithLabelValues(tags["receiver"], tags["result"]).Observe(v)
+	case "event_processing_duration_seconds":
+		s.EventProcessingDurationSeconds.WithLabelValues(tags["receiver"]).Observe(v)
+	case "send_retry_backoff_seconds":
+		s.SendRetryBackoffSeconds.WithLabelValues(tags["receiver"]).Observe(v)
+	}
+}
+
+func (s *Store) Gauge(name string, v float64, tags map[string]string) {
+	switch name {
+	case "last_processed_event_timestamp":
+		s.LastProcessedEventTimestamp.Set(v)
+	}
 }
 
 // promLogger implements promhttp.Logger
@@ -41,42 +207,77 @@ func (pl promLogger) Log(v ...interface{}) error {
 	return nil
 }
 
-func isEventExporterReady() bool {
-	if globalMetricsStore == nil {
-		return false
+// Init wires up every metrics backend configured in cfg for store and
+// returns the resulting Sinks (in backend-configured order). ctx governs the
+// lifetime of any background work Init starts (the Pushgateway pusher and
+// the StatsD client, if configured) - cancel it to shut them down, flushing a
+// final push and closing the StatsD client.
+func Init(ctx context.Context, store *Store, cfg MetricsConfig) ([]Sink, error) {
+	var sinks []Sink
+
+	if cfg.Backend == "" {
+		cfg.Backend = BackendPrometheus
 	}
 
-	// If no events have been processed yet, allow 5 minutes for startup
-	if lastEventProcessedTime.IsZero() {
-		return true
+	// Client-go/workqueue metrics land on store's registry regardless of
+	// which backend(s) below expose it, so an embedder running StatsD-only
+	// can still opt in.
+	if cfg.ClientGoMetrics {
+		if err := InitClientGoMetrics(store, store.prefix); err != nil {
+			return nil, err
+		}
+		if err := InitWorkQueueMetrics(store, store.prefix); err != nil {
+			return nil, err
+		}
 	}
 
-	// Check if we've processed events recently (within 5 minutes)
-	timeSinceLastEvent := time.Since(lastEventProcessedTime)
-	return timeSinceLastEvent < 5*time.Minute
-}
+	// Readiness thresholds only drive the /-/ready endpoint exposed below,
+	// but setting them up here regardless of backend keeps this harmless to
+	// call unconditionally and easy to unit test in isolation.
+	store.SetReadinessConfig(cfg.Readiness)
 
-// SetLastEventProcessedTime updates the timestamp when an event is processed
-func SetLastEventProcessedTime() {
-	lastEventProcessedTime = time.Now()
-}
+	if cfg.Backend == BackendPrometheus || cfg.Backend == BackendBoth {
+		initPrometheusHTTP(store, cfg.Addr, cfg.TLSConfig)
+		if cfg.Push != nil {
+			startPushGateway(ctx, store.registry, cfg.Push)
+		}
+		sinks = append(sinks, store)
+	}
 
-func Init(addr string, tlsConf string) {
-	// Setup the prometheus metrics machinery
-	// Add Go module build info.
-	prometheus.MustRegister(collectors.NewBuildInfoCollector())
+	if cfg.Backend == BackendStatsD || cfg.Backend == BackendBoth {
+		statsdSink, err := NewStatsDSink(cfg.StatsD)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, statsdSink)
+
+		// Flush the buffered client on shutdown so counters/histograms
+		// accumulated since the last FlushInterval tick aren't dropped,
+		// mirroring how the Pushgateway path above uses ctx for a final push.
+		go func() {
+			<-ctx.Done()
+			if err := statsdSink.Close(); err != nil {
+				log.Logger.Error().Err(err).Msg("failed to close statsd client")
+			}
+		}()
+	}
+
+	return sinks, nil
+}
 
+// initPrometheusHTTP serves store's metrics, along with the landing page and
+// health/readiness probes, on addr.
+func initPrometheusHTTP(store *Store, addr string, tlsConf string) {
 	promLogger := promLogger{}
 	metricsPath := "/metrics"
 
+	// Bind to a store-local mux rather than http.DefaultServeMux: a second
+	// Init (e.g. a second Store in the same embedding binary) would
+	// otherwise panic registering a duplicate "/metrics" pattern.
+	mux := http.NewServeMux()
+
 	// Expose the registered metrics via HTTP.
-	http.Handle(metricsPath, promhttp.HandlerFor(
-		prometheus.DefaultGatherer,
-		promhttp.HandlerOpts{
-			// Opt into OpenMetrics to support exemplars.
-			EnableOpenMetrics: true,
-		},
-	))
+	mux.Handle(metricsPath, store.HTTPHandler())
 
 	landingConfig := web.LandingConfig{
 		Name:        "kubernetes-event-exporter",
@@ -89,16 +290,16 @@ func Init(addr string, tlsConf string) {
 		},
 	}
 	landingPage, _ := web.NewLandingPage(landingConfig)
-	http.Handle("/", landingPage)
+	mux.Handle("/", landingPage)
 
-	http.HandleFunc("/-/healthy", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/-/healthy", func(w http.ResponseWriter, r *http.Request) {
 		// Basic health check - just return OK if the service is running
 		w.WriteHeader(http.StatusOK)
 		fmt.Fprintf(w, "OK")
 	})
-	http.HandleFunc("/-/ready", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/-/ready", func(w http.ResponseWriter, r *http.Request) {
 		// Readiness check - verify we're actually processing events
-		if isEventExporterReady() {
+		if store.isReady() {
 			w.WriteHeader(http.StatusOK)
 			fmt.Fprintf(w, "OK")
 		} else {
@@ -108,6 +309,7 @@ func Init(addr string, tlsConf string) {
 	})
 
 	metricsServer := http.Server{
+		Handler:           mux,
 		ReadHeaderTimeout: 5 * time.Second}
 
 	metricsFlags := web.FlagConfig{
@@ -120,9 +322,19 @@ func Init(addr string, tlsConf string) {
 	go web.ListenAndServe(&metricsServer, &metricsFlags, promLogger)
 }
 
+// NewMetricsStore creates a Store backed by its own prometheus.Registry
+// (rather than the global DefaultRegisterer), so that independent Stores -
+// in parallel tests, or in a binary embedding this package - never collide
+// on metric names.
 func NewMetricsStore(name_prefix string) *Store {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewBuildInfoCollector())
+	factory := promauto.With(registry)
+
 	store := &Store{
-		BuildInfo: promauto.NewGaugeFunc(
+		registry: registry,
+		prefix:   name_prefix,
+		BuildInfo: factory.NewGaugeFunc(
 			prometheus.GaugeOpts{
 				Name: name_prefix + "build_info",
 				Help: "A metric with a constant '1' value labeled by version, revision, branch, and goversion from which Kubernetes Event Exporter was built.",
@@ -136,49 +348,106 @@ func NewMetricsStore(name_prefix string) *Store {
 			},
 			func() float64 { return 1 },
 		),
-		EventsProcessed: promauto.NewCounter(prometheus.CounterOpts{
+		EventsProcessed: factory.NewCounterVec(prometheus.CounterOpts{
 			Name: name_prefix + "events_sent",
 			Help: "The total number of events processed",
-		}),
-		EventsDiscarded: promauto.NewCounter(prometheus.CounterOpts{
+		}, eventLabels),
+		EventsDiscarded: factory.NewCounterVec(prometheus.CounterOpts{
 			Name: name_prefix + "events_discarded",
 			Help: "The total number of events discarded because of being older than the maxEventAgeSeconds specified",
-		}),
-		WatchErrors: promauto.NewCounter(prometheus.CounterOpts{
+		}, eventLabels),
+		WatchErrors: factory.NewCounter(prometheus.CounterOpts{
 			Name: name_prefix + "watch_errors",
 			Help: "The total number of errors received from the informer",
 		}),
-		SendErrors: promauto.NewCounter(prometheus.CounterOpts{
+		SendErrors: factory.NewCounterVec(prometheus.CounterOpts{
 			Name: name_prefix + "send_event_errors",
 			Help: "The total number of send event errors",
-		}),
-		KubeApiReadCacheHits: promauto.NewCounter(prometheus.CounterOpts{
+		}, eventLabels),
+		KubeApiReadCacheHits: factory.NewCounter(prometheus.CounterOpts{
 			Name: name_prefix + "kube_api_read_cache_hits",
 			Help: "The total number of read requests served from cache when looking up object metadata",
 		}),
-		KubeApiReadRequests: promauto.NewCounter(prometheus.CounterOpts{
+		KubeApiReadRequests: factory.NewCounter(prometheus.CounterOpts{
 			Name: name_prefix + "kube_api_read_cache_misses",
 			Help: "The total number of read requests served from kube-apiserver when looking up object metadata",
 		}),
-		LastProcessedEventTimestamp: promauto.NewGauge(prometheus.GaugeOpts{
+		LastProcessedEventTimestamp: factory.NewGauge(prometheus.GaugeOpts{
 			Name: name_prefix + "last_processed_event_timestamp",
 			Help: "The timestamp of the last successfully processed event",
 		}),
+		SendDurationSeconds: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    name_prefix + "send_duration_seconds",
+			Help:    "The time it took each sink Send call to complete",
+			Buckets: sendDurationBuckets,
+		}, []string{"receiver", "result"}),
+		EventProcessingDurationSeconds: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    name_prefix + "event_processing_duration_seconds",
+			Help:    "The time between receiving a Kubernetes event and successfully dispatching it",
+			Buckets: sendDurationBuckets,
+		}, []string{"receiver"}),
+		SendRetriesTotal: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: name_prefix + "send_retries_total",
+			Help: "The total number of times a sink Send call was retried, labeled by attempt number",
+		}, []string{"receiver", "attempt"}),
+		SendRetryBackoffSeconds: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    name_prefix + "send_retry_backoff_seconds",
+			Help:    "The backoff duration waited before retrying a sink Send call",
+			Buckets: sendDurationBuckets,
+		}, []string{"receiver"}),
+		EventsProcessedRate1m: factory.NewGauge(prometheus.GaugeOpts{
+			Name: name_prefix + "events_processed_rate_1m",
+			Help: "The average number of events processed per minute over the last 1 minute",
+		}),
+		EventsProcessedRate5m: factory.NewGauge(prometheus.GaugeOpts{
+			Name: name_prefix + "events_processed_rate_5m",
+			Help: "The average number of events processed per minute over the last 5 minutes",
+		}),
+		EventsProcessedRate15m: factory.NewGauge(prometheus.GaugeOpts{
+			Name: name_prefix + "events_processed_rate_15m",
+			Help: "The average number of events processed per minute over the last 15 minutes",
+		}),
+		rate:            &eventRateTracker{},
+		stopped:         make(chan struct{}),
+		readinessConfig: defaultReadinessConfig,
 	}
 
-	// Store global reference for health checks
-	globalMetricsStore = store
+	store.startRateRefresher(10 * time.Second)
+
 	return store
 }
 
+// startRateRefresher periodically recomputes the 1m/5m/15m rate gauges from
+// the ring buffer until the store is destroyed.
+func (s *Store) startRateRefresher(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.refreshRateGauges(time.Now())
+			case <-s.stopped:
+				return
+			}
+		}
+	}()
+}
+
+func (s *Store) refreshRateGauges(now time.Time) {
+	s.EventsProcessedRate1m.Set(s.rate.Rate(now, time.Minute))
+	s.EventsProcessedRate5m.Set(s.rate.Rate(now, 5*time.Minute))
+	s.EventsProcessedRate15m.Set(s.rate.Rate(now, 15*time.Minute))
+}
+
+// DestroyMetricsStore stops the store's background goroutines. Since each
+// Store owns its own registry rather than registering into the global
+// DefaultRegisterer, there's nothing to individually Unregister - the
+// registry, and every metric on it, becomes eligible for GC once store is
+// dropped.
 func DestroyMetricsStore(store *Store) {
-	prometheus.Unregister(store.EventsProcessed)
-	prometheus.Unregister(store.EventsDiscarded)
-	prometheus.Unregister(store.WatchErrors)
-	prometheus.Unregister(store.SendErrors)
-	prometheus.Unregister(store.BuildInfo)
-	prometheus.Unregister(store.KubeApiReadCacheHits)
-	prometheus.Unregister(store.KubeApiReadRequests)
-	prometheus.Unregister(store.LastProcessedEventTimestamp)
-	store = nil
+	store.destroyOnce.Do(func() {
+		close(store.stopped)
+	})
 }
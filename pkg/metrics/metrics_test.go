@@ -3,6 +3,8 @@ package metrics
 import (
 	"testing"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 func TestNewMetricsStore(t *testing.T) {
@@ -40,33 +42,26 @@ func TestNewMetricsStore(t *testing.T) {
 	if store.LastProcessedEventTimestamp == nil {
 		t.Error("LastProcessedEventTimestamp not initialized")
 	}
-}
-
-func TestSetLastEventProcessedTime(t *testing.T) {
-	// Save original state
-	originalTime := lastEventProcessedTime
-	defer func() { lastEventProcessedTime = originalTime }()
-
-	// Initially should be zero time
-	lastEventProcessedTime = time.Time{}
-	if !lastEventProcessedTime.IsZero() {
-		t.Error("Expected lastEventProcessedTime to be zero initially")
+	if store.SendDurationSeconds == nil {
+		t.Error("SendDurationSeconds not initialized")
 	}
-
-	// Set the time
-	before := time.Now()
-	SetLastEventProcessedTime()
-	after := time.Now()
-
-	// Verify the time was set to something reasonable
-	if lastEventProcessedTime.IsZero() {
-		t.Error("Expected lastEventProcessedTime to be set after calling SetLastEventProcessedTime")
+	if store.EventProcessingDurationSeconds == nil {
+		t.Error("EventProcessingDurationSeconds not initialized")
+	}
+	if store.SendRetriesTotal == nil {
+		t.Error("SendRetriesTotal not initialized")
 	}
-	if lastEventProcessedTime.Before(before) {
-		t.Error("lastEventProcessedTime should not be before the call")
+	if store.SendRetryBackoffSeconds == nil {
+		t.Error("SendRetryBackoffSeconds not initialized")
 	}
-	if lastEventProcessedTime.After(after) {
-		t.Error("lastEventProcessedTime should not be after the call")
+	if store.EventsProcessedRate1m == nil {
+		t.Error("EventsProcessedRate1m not initialized")
+	}
+	if store.EventsProcessedRate5m == nil {
+		t.Error("EventsProcessedRate5m not initialized")
+	}
+	if store.EventsProcessedRate15m == nil {
+		t.Error("EventsProcessedRate15m not initialized")
 	}
 }
 
@@ -76,23 +71,33 @@ func TestDestroyMetricsStore(t *testing.T) {
 	if store == nil {
 		t.Fatal("Failed to create metrics store")
 	}
-	if globalMetricsStore != store {
-		t.Error("globalMetricsStore should be set to the new store")
-	}
 
 	// Destroy it - should not panic
 	DestroyMetricsStore(store)
 }
 
+func TestParallelStoresDoNotCollide(t *testing.T) {
+	// Two stores using the same prefix used to collide on the global
+	// DefaultRegisterer; each now owns its own registry.
+	storeA := NewMetricsStore("test_parallel_")
+	defer DestroyMetricsStore(storeA)
+	storeB := NewMetricsStore("test_parallel_")
+	defer DestroyMetricsStore(storeB)
+
+	storeA.IncEventsProcessed("stdout", "Normal", "default", "")
+	if got := testutil.ToFloat64(storeB.EventsProcessed.WithLabelValues("stdout", "Normal", "default", "")); got != 0 {
+		t.Errorf("expected storeB to be unaffected by storeA, got %v", got)
+	}
+}
+
 func TestMetricsStoreBasicFunctionality(t *testing.T) {
 	// Integration test for basic functionality
 	store := NewMetricsStore("integration_test_")
 	defer DestroyMetricsStore(store)
 
 	// Simulate event processing
-	store.EventsProcessed.Inc()
+	store.IncEventsProcessed("dummy", "Normal", "default", "")
 	store.LastProcessedEventTimestamp.SetToCurrentTime()
-	SetLastEventProcessedTime()
 
 	// Test that the metrics are accessible
 	if store.EventsProcessed == nil {
@@ -102,3 +107,82 @@ func TestMetricsStoreBasicFunctionality(t *testing.T) {
 		t.Error("LastProcessedEventTimestamp should not be nil")
 	}
 }
+
+func TestIncEventsProcessedLabels(t *testing.T) {
+	store := NewMetricsStore("test_inc_")
+	defer DestroyMetricsStore(store)
+
+	store.IncEventsProcessed("stdout", "Warning", "kube-system", "FailedScheduling")
+	store.IncEventsDiscarded("stdout", "Normal", "kube-system", "tooOld")
+	store.IncSendErrors("elasticsearch", "Warning", "default", "timeout")
+
+	if got := testutil.ToFloat64(store.EventsProcessed.WithLabelValues("stdout", "Warning", "kube-system", "FailedScheduling")); got != 1 {
+		t.Errorf("expected EventsProcessed to be 1, got %v", got)
+	}
+	if got := testutil.ToFloat64(store.EventsDiscarded.WithLabelValues("stdout", "Normal", "kube-system", "tooOld")); got != 1 {
+		t.Errorf("expected EventsDiscarded to be 1, got %v", got)
+	}
+	if got := testutil.ToFloat64(store.SendErrors.WithLabelValues("elasticsearch", "Warning", "default", "timeout")); got != 1 {
+		t.Errorf("expected SendErrors to be 1, got %v", got)
+	}
+}
+
+func TestObserveSend(t *testing.T) {
+	store := NewMetricsStore("test_duration_")
+	defer DestroyMetricsStore(store)
+
+	store.ObserveSend("stdout", "success", 42*time.Millisecond)
+
+	if count := testutil.CollectAndCount(store.SendDurationSeconds); count != 1 {
+		t.Errorf("expected 1 observation, got %d", count)
+	}
+}
+
+func TestStartTimerObservesDuration(t *testing.T) {
+	store := NewMetricsStore("test_timer_")
+	defer DestroyMetricsStore(store)
+
+	stop := store.StartTimer("stdout")
+	time.Sleep(time.Millisecond)
+	stop()
+
+	if count := testutil.CollectAndCount(store.EventProcessingDurationSeconds); count != 1 {
+		t.Errorf("expected 1 observation, got %d", count)
+	}
+}
+
+func TestSendRetryMetrics(t *testing.T) {
+	store := NewMetricsStore("test_retry_")
+	defer DestroyMetricsStore(store)
+
+	store.IncSendRetries("elasticsearch", 1)
+	store.IncSendRetries("elasticsearch", 2)
+	store.ObserveSendRetryBackoff("elasticsearch", 250*time.Millisecond)
+
+	if got := testutil.ToFloat64(store.SendRetriesTotal.WithLabelValues("elasticsearch", "1")); got != 1 {
+		t.Errorf("expected attempt 1 retries to be 1, got %v", got)
+	}
+	if got := testutil.ToFloat64(store.SendRetriesTotal.WithLabelValues("elasticsearch", "2")); got != 1 {
+		t.Errorf("expected attempt 2 retries to be 1, got %v", got)
+	}
+	if count := testutil.CollectAndCount(store.SendRetryBackoffSeconds); count != 1 {
+		t.Errorf("expected 1 backoff observation, got %d", count)
+	}
+}
+
+func TestStoreImplementsSink(t *testing.T) {
+	store := NewMetricsStore("test_sink_")
+	defer DestroyMetricsStore(store)
+
+	var sink Sink = store
+	sink.Inc("events_sent", map[string]string{"receiver": "stdout", "event_type": "Normal", "namespace": "default", "reason": ""})
+	sink.Observe("send_duration_seconds", 0.1, map[string]string{"receiver": "stdout", "result": "success"})
+	sink.Gauge("last_processed_event_timestamp", 42, nil)
+
+	if got := testutil.ToFloat64(store.EventsProcessed.WithLabelValues("stdout", "Normal", "default", "")); got != 1 {
+		t.Errorf("expected EventsProcessed to be 1, got %v", got)
+	}
+	if got := testutil.ToFloat64(store.LastProcessedEventTimestamp); got != 42 {
+		t.Errorf("expected LastProcessedEventTimestamp to be 42, got %v", got)
+	}
+}
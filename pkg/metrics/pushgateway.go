@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"github.com/rs/zerolog/log"
+)
+
+// PushConfig configures periodic pushes of the registered metrics to a
+// Prometheus Pushgateway, for short-lived or batch invocations where
+// scraping isn't practical.
+type PushConfig struct {
+	// URL is the Pushgateway base URL, e.g. "http://pushgateway:9091".
+	URL string
+	// Job is the Pushgateway job label.
+	Job string
+	// Grouping holds additional grouping labels, e.g. "instance", "cluster".
+	Grouping map[string]string
+	// Interval is how often to push. Defaults to 15s if zero.
+	Interval time.Duration
+
+	BasicAuthUser     string
+	BasicAuthPassword string
+	// TLSConfig, if set, is the path to a PEM-encoded CA certificate bundle
+	// used to verify the Pushgateway's TLS certificate, for gateways behind
+	// a private CA.
+	TLSConfig string
+
+	// DeleteOnShutdown removes the pushed group from the Pushgateway after
+	// the final push on shutdown.
+	DeleteOnShutdown bool
+}
+
+// resolvePushInterval returns interval, defaulting to 15s if unset.
+func resolvePushInterval(interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return 15 * time.Second
+	}
+	return interval
+}
+
+// buildPusher constructs the Pushgateway pusher for cfg, wiring grouping
+// labels, basic auth, and - if cfg.TLSConfig is set - a client that verifies
+// the gateway's certificate against that CA bundle instead of only the
+// system root pool.
+func buildPusher(gatherer prometheus.Gatherer, cfg *PushConfig) *push.Pusher {
+	pusher := push.New(cfg.URL, cfg.Job).Gatherer(gatherer)
+	for name, value := range cfg.Grouping {
+		pusher = pusher.Grouping(name, value)
+	}
+	if cfg.BasicAuthUser != "" {
+		pusher = pusher.BasicAuth(cfg.BasicAuthUser, cfg.BasicAuthPassword)
+	}
+	if cfg.TLSConfig != "" {
+		tlsConfig, err := tlsConfigFromCAFile(cfg.TLSConfig)
+		if err != nil {
+			log.Logger.Error().Err(err).Str("file", cfg.TLSConfig).Msg("failed to load pushgateway TLS config, pushing with the default HTTP client")
+		} else {
+			pusher = pusher.Client(&http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}})
+		}
+	}
+	return pusher
+}
+
+// tlsConfigFromCAFile builds a tls.Config that verifies a peer's certificate
+// against the PEM-encoded CA bundle at path.
+func tlsConfigFromCAFile(path string) (*tls.Config, error) {
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemBytes) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+
+	return &tls.Config{RootCAs: pool}, nil
+}
+
+// startPushGateway periodically pushes gatherer to the configured
+// Pushgateway until ctx is done, at which point it does one final push and,
+// if configured, deletes the group.
+func startPushGateway(ctx context.Context, gatherer prometheus.Gatherer, cfg *PushConfig) {
+	interval := resolvePushInterval(cfg.Interval)
+	pusher := buildPusher(gatherer, cfg)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := pusher.Push(); err != nil {
+					log.Logger.Error().Err(err).Msg("failed to push metrics to pushgateway")
+				}
+			case <-ctx.Done():
+				if err := pusher.Push(); err != nil {
+					log.Logger.Error().Err(err).Msg("failed final push to pushgateway")
+				}
+				if cfg.DeleteOnShutdown {
+					if err := pusher.Delete(); err != nil {
+						log.Logger.Error().Err(err).Msg("failed to delete group from pushgateway")
+					}
+				}
+				return
+			}
+		}
+	}()
+}
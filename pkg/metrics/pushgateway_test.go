@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestResolvePushInterval(t *testing.T) {
+	if got := resolvePushInterval(0); got != 15*time.Second {
+		t.Errorf("expected default interval of 15s, got %v", got)
+	}
+	if got := resolvePushInterval(2 * time.Second); got != 2*time.Second {
+		t.Errorf("expected a configured interval to be preserved, got %v", got)
+	}
+}
+
+func TestBuildPusherSendsGroupingAndBasicAuth(t *testing.T) {
+	var gotPath string
+	var gotUser, gotPass string
+	var gotOK bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotUser, gotPass, gotOK = r.BasicAuth()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := &PushConfig{
+		URL:               server.URL,
+		Job:               "event-exporter",
+		Grouping:          map[string]string{"instance": "test-instance"},
+		BasicAuthUser:     "user",
+		BasicAuthPassword: "pass",
+	}
+
+	pusher := buildPusher(prometheus.NewRegistry(), cfg)
+	if err := pusher.Push(); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+
+	if !strings.Contains(gotPath, "event-exporter") || !strings.Contains(gotPath, "test-instance") {
+		t.Errorf("expected job and grouping labels in the push path, got %q", gotPath)
+	}
+	if !gotOK || gotUser != "user" || gotPass != "pass" {
+		t.Errorf("expected basic auth credentials to be sent, got user=%q pass=%q ok=%v", gotUser, gotPass, gotOK)
+	}
+}
+
+func TestBuildPusherFallsBackWhenTLSConfigInvalid(t *testing.T) {
+	cfg := &PushConfig{URL: "http://pushgateway.invalid", Job: "job", TLSConfig: "/nonexistent/ca.pem"}
+
+	if pusher := buildPusher(prometheus.NewRegistry(), cfg); pusher == nil {
+		t.Fatal("expected a non-nil pusher even when the TLS config fails to load")
+	}
+}
+
+func TestTLSConfigFromCAFile(t *testing.T) {
+	path := writeTestCACert(t)
+
+	tlsConfig, err := tlsConfigFromCAFile(path)
+	if err != nil {
+		t.Fatalf("tlsConfigFromCAFile returned error: %v", err)
+	}
+	if tlsConfig.RootCAs == nil {
+		t.Error("expected RootCAs to be populated from the CA file")
+	}
+}
+
+func TestTLSConfigFromCAFileMissing(t *testing.T) {
+	if _, err := tlsConfigFromCAFile("/nonexistent/ca.pem"); err == nil {
+		t.Error("expected an error for a missing CA file")
+	}
+}
+
+func TestTLSConfigFromCAFileInvalidPEM(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(path, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("failed to write CA file: %v", err)
+	}
+
+	if _, err := tlsConfigFromCAFile(path); err == nil {
+		t.Error("expected an error for a CA file with no valid certificates")
+	}
+}
+
+// writeTestCACert writes a minimal self-signed CA certificate to a temp file
+// and returns its path.
+func writeTestCACert(t *testing.T) string {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate test key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test-ca"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+		KeyUsage:     x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create test certificate: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := os.WriteFile(path, pemBytes, 0o600); err != nil {
+		t.Fatalf("failed to write CA file: %v", err)
+	}
+	return path
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import "time"
+
+// Sink is the minimal set of operations a metrics backend must support so
+// that the rest of the exporter can emit metrics without caring whether
+// they end up in Prometheus, StatsD, or both.
+type Sink interface {
+	// Inc increments a counter by 1.
+	Inc(name string, tags map[string]string)
+	// Observe records a single value for a histogram/timing metric.
+	Observe(name string, v float64, tags map[string]string)
+	// Gauge sets a gauge to the given value.
+	Gauge(name string, v float64, tags map[string]string)
+}
+
+// Backend selects which metrics sink(s) Init wires up.
+type Backend string
+
+const (
+	BackendPrometheus Backend = "prometheus"
+	BackendStatsD     Backend = "statsd"
+	BackendBoth       Backend = "both"
+)
+
+// MetricsConfig configures the metrics backend(s) the exporter reports to.
+type MetricsConfig struct {
+	// Backend selects prometheus (default), statsd, or both.
+	Backend Backend
+
+	// Addr and TLSConfig configure the Prometheus HTTP listener, used when
+	// Backend is BackendPrometheus or BackendBoth.
+	Addr      string
+	TLSConfig string
+
+	// StatsD configures the StatsD/DogStatsD client, used when Backend is
+	// BackendStatsD or BackendBoth.
+	StatsD StatsDConfig
+
+	// Push, when non-nil, additionally pushes metrics to a Prometheus
+	// Pushgateway on a timer instead of relying solely on scraping. Only
+	// used when Backend is BackendPrometheus or BackendBoth.
+	Push *PushConfig
+
+	// ClientGoMetrics, when true, wires the client-go rest-client and
+	// workqueue metrics (API request latency/results, informer queue depth
+	// and latency) into the same registry as the exporter's own metrics.
+	ClientGoMetrics bool
+
+	// Readiness tunes the /-/ready endpoint's windowed event-rate
+	// healthcheck, used when Backend is BackendPrometheus or BackendBoth.
+	// The zero value applies ReadinessConfig's defaults.
+	Readiness ReadinessConfig
+}
+
+// StatsDConfig configures the StatsD/DogStatsD client.
+type StatsDConfig struct {
+	Host          string
+	Port          int
+	Prefix        string
+	FlushInterval time.Duration
+	SampleRate    float64
+}
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+	"github.com/rs/zerolog/log"
+)
+
+// StatsDSink reports metrics to a StatsD/DogStatsD agent. It implements Sink.
+type StatsDSink struct {
+	client *statsd.Client
+	rate   float64
+}
+
+// NewStatsDSink dials a StatsD/DogStatsD client from the given config.
+func NewStatsDSink(cfg StatsDConfig) (*StatsDSink, error) {
+	opts := []statsd.Option{
+		statsd.WithNamespace(cfg.Prefix),
+	}
+	if cfg.FlushInterval > 0 {
+		opts = append(opts, statsd.WithBufferFlushInterval(cfg.FlushInterval))
+	}
+
+	client, err := statsd.New(fmt.Sprintf("%s:%d", cfg.Host, cfg.Port), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create statsd client: %w", err)
+	}
+
+	rate := cfg.SampleRate
+	if rate <= 0 {
+		rate = 1
+	}
+
+	return &StatsDSink{client: client, rate: rate}, nil
+}
+
+func tagsToSlice(tags map[string]string) []string {
+	out := make([]string, 0, len(tags))
+	for k, v := range tags {
+		out = append(out, k+":"+v)
+	}
+	return out
+}
+
+func (s *StatsDSink) Inc(name string, tags map[string]string) {
+	if err := s.client.Incr(name, tagsToSlice(tags), s.rate); err != nil {
+		log.Logger.Error().Err(err).Str("metric", name).Msg("failed to send statsd counter")
+	}
+}
+
+func (s *StatsDSink) Observe(name string, v float64, tags map[string]string) {
+	if err := s.client.Histogram(name, v, tagsToSlice(tags), s.rate); err != nil {
+		log.Logger.Error().Err(err).Str("metric", name).Msg("failed to send statsd histogram")
+	}
+}
+
+func (s *StatsDSink) Gauge(name string, v float64, tags map[string]string) {
+	if err := s.client.Gauge(name, v, tagsToSlice(tags), s.rate); err != nil {
+		log.Logger.Error().Err(err).Str("metric", name).Msg("failed to send statsd gauge")
+	}
+}
+
+// Close flushes and closes the underlying StatsD client.
+func (s *StatsDSink) Close() error {
+	return s.client.Close()
+}
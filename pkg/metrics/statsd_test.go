@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestTagsToSlice(t *testing.T) {
+	got := tagsToSlice(map[string]string{"receiver": "slack", "result": "success"})
+	sort.Strings(got)
+
+	want := []string{"receiver:slack", "result:success"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, got)
+			break
+		}
+	}
+}
+
+func TestTagsToSliceEmpty(t *testing.T) {
+	if got := tagsToSlice(nil); len(got) != 0 {
+		t.Errorf("expected an empty slice for nil tags, got %v", got)
+	}
+}
+
+func TestNewStatsDSinkDefaultsSampleRate(t *testing.T) {
+	sink, err := NewStatsDSink(StatsDConfig{Host: "127.0.0.1", Port: 8125})
+	if err != nil {
+		t.Fatalf("NewStatsDSink returned error: %v", err)
+	}
+	defer sink.Close()
+
+	if sink.rate != 1 {
+		t.Errorf("expected default sample rate of 1, got %v", sink.rate)
+	}
+}
+
+func TestNewStatsDSinkUsesConfiguredSampleRate(t *testing.T) {
+	sink, err := NewStatsDSink(StatsDConfig{Host: "127.0.0.1", Port: 8125, SampleRate: 0.25})
+	if err != nil {
+		t.Fatalf("NewStatsDSink returned error: %v", err)
+	}
+	defer sink.Close()
+
+	if sink.rate != 0.25 {
+		t.Errorf("expected configured sample rate of 0.25, got %v", sink.rate)
+	}
+}
+
+func TestNewStatsDSinkAcceptsFlushInterval(t *testing.T) {
+	sink, err := NewStatsDSink(StatsDConfig{Host: "127.0.0.1", Port: 8125, FlushInterval: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewStatsDSink returned error: %v", err)
+	}
+	defer sink.Close()
+
+	if sink.client == nil {
+		t.Error("expected a configured statsd client")
+	}
+}